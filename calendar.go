@@ -0,0 +1,80 @@
+package gtfs
+
+import "time"
+
+// CalendarException records a calendar_dates.txt exception for a single
+// service on a single date: whether service was added (exception_type 1) or
+// removed (exception_type 2) on that date.
+type CalendarException struct {
+	Added bool
+}
+
+// ActiveServices returns the service IDs that run on day, combining the
+// weekly mask from calendar.txt with any calendar_dates.txt exceptions for
+// that date. Exceptions always take precedence over the weekly mask.
+func (feed Feed) ActiveServices(day time.Time) []string {
+	dayIdx := (int(day.Weekday()) + 6) % 7 // Days is Monday..Sunday; time.Weekday is Sunday..Saturday
+	dateKey := day.Format("20060102")
+	// Start/End come from parseGTFSDate and so carry no clock component;
+	// compare by calendar date rather than instant, or a day's end date
+	// would wrongly exclude any query carrying a time of day (e.g. time.Now()).
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+
+	active := make(map[string]bool)
+	for id, c := range feed.CalendarEntries {
+		if len(c.Days) == 7 && c.Days[dayIdx] == "1" && !date.Before(c.Start) && !date.After(c.End) {
+			active[id] = true
+		}
+	}
+	for id, exceptions := range feed.CalendarExceptions {
+		if ex, ok := exceptions[dateKey]; ok {
+			if ex.Added {
+				active[id] = true
+			} else {
+				delete(active, id)
+			}
+		}
+	}
+
+	retval := []string{}
+	for id := range active {
+		retval = append(retval, id)
+	}
+	return retval
+}
+
+// TripsOn returns every trip in the feed whose service runs on day.
+func (feed Feed) TripsOn(day time.Time) []*Trip {
+	services := make(map[string]bool)
+	for _, id := range feed.ActiveServices(day) {
+		services[id] = true
+	}
+
+	retval := []*Trip{}
+	for _, t := range feed.Trips {
+		if services[t.Service] {
+			retval = append(retval, t)
+		}
+	}
+	return retval
+}
+
+// TripsOn returns every trip on this route whose service runs on day.
+func (route Route) TripsOn(day time.Time) []*Trip {
+	if route.feed == nil {
+		return nil
+	}
+
+	services := make(map[string]bool)
+	for _, id := range route.feed.ActiveServices(day) {
+		services[id] = true
+	}
+
+	retval := []*Trip{}
+	for _, t := range route.Trips {
+		if services[t.Service] {
+			retval = append(retval, t)
+		}
+	}
+	return retval
+}
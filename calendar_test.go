@@ -0,0 +1,68 @@
+package gtfs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActiveServicesIncludesBoundaryDayWithTimeOfDay(t *testing.T) {
+	// Sunday-only service, valid through 2026-07-26 (a Sunday).
+	feed := Feed{
+		CalendarEntries: map[string]CalendarEntry{
+			"sunday-only": {
+				ServiceID: "sunday-only",
+				Days:      []string{"0", "0", "0", "0", "0", "0", "1"},
+				Start:     parseGTFSDate("20260101"),
+				End:       parseGTFSDate("20260726"),
+			},
+		},
+		CalendarExceptions: map[string]map[string]CalendarException{},
+	}
+
+	day := time.Date(2026, time.July, 26, 15, 0, 0, 0, time.UTC)
+	active := feed.ActiveServices(day)
+
+	if len(active) != 1 || active[0] != "sunday-only" {
+		t.Fatalf("ActiveServices(%v) = %v, want [sunday-only]", day, active)
+	}
+}
+
+func TestActiveServicesExcludesDayAfterEnd(t *testing.T) {
+	feed := Feed{
+		CalendarEntries: map[string]CalendarEntry{
+			"sunday-only": {
+				ServiceID: "sunday-only",
+				Days:      []string{"0", "0", "0", "0", "0", "0", "1"},
+				Start:     parseGTFSDate("20260101"),
+				End:       parseGTFSDate("20260726"),
+			},
+		},
+		CalendarExceptions: map[string]map[string]CalendarException{},
+	}
+
+	day := time.Date(2026, time.August, 2, 0, 0, 0, 0, time.UTC)
+	if active := feed.ActiveServices(day); len(active) != 0 {
+		t.Fatalf("ActiveServices(%v) = %v, want none", day, active)
+	}
+}
+
+func TestActiveServicesExceptionOverridesCalendar(t *testing.T) {
+	feed := Feed{
+		CalendarEntries: map[string]CalendarEntry{
+			"weekday": {
+				ServiceID: "weekday",
+				Days:      []string{"1", "1", "1", "1", "1", "0", "0"},
+				Start:     parseGTFSDate("20260101"),
+				End:       parseGTFSDate("20261231"),
+			},
+		},
+		CalendarExceptions: map[string]map[string]CalendarException{
+			"weekday": {"20260727": {Added: false}},
+		},
+	}
+
+	day := time.Date(2026, time.July, 27, 0, 0, 0, 0, time.UTC) // a Monday
+	if active := feed.ActiveServices(day); len(active) != 0 {
+		t.Fatalf("ActiveServices(%v) = %v, want none (removed by exception)", day, active)
+	}
+}
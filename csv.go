@@ -0,0 +1,121 @@
+package gtfs
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// RowError records why a single row of a GTFS file failed to parse.
+type RowError struct {
+	File string
+	Row  int
+	Err  error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Row, e.Err)
+}
+
+// LoadReport accumulates the rows that failed to parse during a Load,
+// instead of those failures being silently dropped.
+type LoadReport struct {
+	Errors []RowError
+}
+
+func (r *LoadReport) addError(file string, row int, err error) {
+	r.Errors = append(r.Errors, RowError{File: file, Row: row, Err: err})
+}
+
+// RowHandler lets a caller register interest in a GTFS file this module
+// doesn't parse into a first-class field, such as fare_rules.txt. Handlers
+// are run after the feed's built-in files have all been loaded.
+type RowHandler interface {
+	// Filename is the GTFS file this handler wants rows from, e.g. "fare_rules.txt".
+	Filename() string
+	// HandleRow is called once per data row, keyed by column name.
+	HandleRow(row map[string]string) error
+}
+
+// readCsv streams filename row by row out of feed.fsys, calling f once per
+// data row rather than materializing the whole file in memory. Rows that
+// fail to parse (f returns an error, or the CSV itself is malformed) are
+// recorded in feed.Report rather than aborting the load. Missing optional
+// trailing columns are left as empty strings.
+func (feed *Feed) readCsv(filename string, f func(row map[string]string) error) error {
+	file, err := feed.fsys.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: reading header: %w", filename, err)
+	}
+	if len(header) > 0 {
+		// Excel/Windows exporters routinely prefix the first file in a GTFS
+		// feed with a UTF-8 BOM; left in place it silently merges into the
+		// first column's name (e.g. "route_id" becomes "\ufeffroute_id"),
+		// corrupting that file's primary key.
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	for i, col := range header {
+		header[i] = strings.TrimSpace(col)
+	}
+
+	rowNum := 0
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		rowNum++
+		if err != nil {
+			feed.Report.addError(filename, rowNum, err)
+			continue
+		}
+
+		row := make(map[string]string, len(header))
+		for i, col := range header {
+			if i < len(record) {
+				row[col] = strings.TrimSpace(record[i])
+			}
+		}
+
+		if err := f(row); err != nil {
+			feed.Report.addError(filename, rowNum, err)
+		}
+	}
+
+	return nil
+}
+
+// readOptionalCsv behaves like readCsv, but treats a missing file as success
+// rather than an error, for GTFS files the spec marks optional.
+func (feed *Feed) readOptionalCsv(filename string, f func(row map[string]string) error) error {
+	if err := feed.readCsv(filename, f); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// runRowHandlers feeds each registered RowHandler's file through
+// readOptionalCsv, since extension files like this are typically optional.
+func (feed *Feed) runRowHandlers(handlers []RowHandler) error {
+	for _, h := range handlers {
+		if err := feed.readOptionalCsv(h.Filename(), h.HandleRow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package gtfs
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestReadCsvStripsLeadingBOM(t *testing.T) {
+	feed := &Feed{fsys: fstest.MapFS{
+		"routes.txt": &fstest.MapFile{Data: []byte("\ufeffroute_id,route_short_name\nR1,1\n")},
+	}}
+
+	var rows []map[string]string
+	if err := feed.readCsv("routes.txt", func(row map[string]string) error {
+		rows = append(rows, row)
+		return nil
+	}); err != nil {
+		t.Fatalf("readCsv: %v", err)
+	}
+
+	if len(rows) != 1 || rows[0]["route_id"] != "R1" {
+		t.Fatalf("rows = %v, want [{route_id:R1 ...}]", rows)
+	}
+}
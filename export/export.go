@@ -0,0 +1,105 @@
+// Package export builds GeoJSON features and Google Encoded Polyline
+// strings from plain coordinate data. It has no dependency on the gtfs
+// package; gtfs's own GeoJSON/polyline methods are thin wrappers around it.
+package export
+
+import (
+	"math"
+	"strings"
+)
+
+// Feature is a single GeoJSON Feature.
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   Geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Geometry is a GeoJSON geometry object. Coordinates holds whatever nesting
+// the geometry Type requires ([]float64 for Point, [][]float64 for
+// LineString, and so on).
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// FeatureCollection is a GeoJSON FeatureCollection.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// NewFeatureCollection wraps features into a GeoJSON FeatureCollection.
+func NewFeatureCollection(features ...Feature) FeatureCollection {
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+// PointFeature builds a GeoJSON Point Feature at (lon, lat).
+func PointFeature(lon, lat float64, properties map[string]interface{}) Feature {
+	return Feature{
+		Type:       "Feature",
+		Geometry:   Geometry{Type: "Point", Coordinates: []float64{lon, lat}},
+		Properties: properties,
+	}
+}
+
+// LineStringFeature builds a GeoJSON LineString Feature from a path of
+// (lon, lat) coordinates.
+func LineStringFeature(coords [][2]float64, properties map[string]interface{}) Feature {
+	return Feature{
+		Type:       "Feature",
+		Geometry:   Geometry{Type: "LineString", Coordinates: toCoordinates(coords)},
+		Properties: properties,
+	}
+}
+
+// MultiLineStringFeature builds a GeoJSON MultiLineString Feature from a set
+// of paths, each a list of (lon, lat) coordinates.
+func MultiLineStringFeature(lines [][][2]float64, properties map[string]interface{}) Feature {
+	coordinates := make([][][]float64, len(lines))
+	for i, line := range lines {
+		coordinates[i] = toCoordinates(line)
+	}
+	return Feature{
+		Type:       "Feature",
+		Geometry:   Geometry{Type: "MultiLineString", Coordinates: coordinates},
+		Properties: properties,
+	}
+}
+
+func toCoordinates(coords [][2]float64) [][]float64 {
+	coordinates := make([][]float64, len(coords))
+	for i, c := range coords {
+		coordinates[i] = []float64{c[0], c[1]}
+	}
+	return coordinates
+}
+
+// EncodePolyline renders a path of (lon, lat) coordinates as a Google
+// Encoded Polyline Algorithm Format string.
+func EncodePolyline(coords [][2]float64) string {
+	var buf strings.Builder
+	var prevLat, prevLon int
+
+	for _, c := range coords {
+		lat := int(math.Round(c[1] * 1e5))
+		lon := int(math.Round(c[0] * 1e5))
+		encodePolylineValue(&buf, lat-prevLat)
+		encodePolylineValue(&buf, lon-prevLon)
+		prevLat, prevLon = lat, lon
+	}
+
+	return buf.String()
+}
+
+func encodePolylineValue(buf *strings.Builder, v int) {
+	v <<= 1
+	if v < 0 {
+		v = ^v
+	}
+	for v >= 0x20 {
+		buf.WriteByte(byte((0x20 | (v & 0x1f)) + 63))
+		v >>= 5
+	}
+	buf.WriteByte(byte(v + 63))
+}
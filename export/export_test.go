@@ -0,0 +1,19 @@
+package export
+
+import "testing"
+
+func TestEncodePolyline(t *testing.T) {
+	// The canonical example from Google's Encoded Polyline Algorithm Format
+	// documentation: (lat, lon) pairs (38.5,-120.2), (40.7,-120.95), (43.252,-126.453).
+	coords := [][2]float64{
+		{-120.2, 38.5},
+		{-120.95, 40.7},
+		{-126.453, 43.252},
+	}
+
+	got := EncodePolyline(coords)
+	want := "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+	if got != want {
+		t.Fatalf("EncodePolyline(%v) = %q, want %q", coords, got, want)
+	}
+}
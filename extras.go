@@ -0,0 +1,120 @@
+package gtfs
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Agency is a parsed agency.txt row: one operator that may run any number of
+// routes.
+type Agency struct {
+	ID       string
+	Name     string
+	URL      string
+	Timezone string
+}
+
+// Transfer is a parsed transfers.txt row describing how riders may move
+// between two stops.
+type Transfer struct {
+	FromStopID      string
+	ToStopID        string
+	Type            int
+	MinTransferTime *int
+}
+
+// Frequency is a parsed frequencies.txt row: a trip that repeats on a
+// headway rather than running once at its stop_times.txt times.
+type Frequency struct {
+	TripID      string
+	StartTime   int
+	EndTime     int
+	HeadwaySecs int
+}
+
+// FeedInfo is the parsed, singular feed_info.txt row describing the feed
+// itself.
+type FeedInfo struct {
+	PublisherName string
+	PublisherURL  string
+	Lang          string
+	StartDate     time.Time
+	EndDate       time.Time
+	Version       string
+}
+
+// loadAgencies populates Feed.Agencies from agency.txt.
+func (feed *Feed) loadAgencies() error {
+	return feed.readOptionalCsv("agency.txt", func(row map[string]string) error {
+		a := &Agency{
+			ID:       row["agency_id"],
+			Name:     row["agency_name"],
+			URL:      row["agency_url"],
+			Timezone: row["agency_timezone"],
+		}
+		feed.Agencies[a.ID] = a
+		return nil
+	})
+}
+
+// loadTransfers populates Feed.Transfers from transfers.txt.
+func (feed *Feed) loadTransfers() error {
+	return feed.readOptionalCsv("transfers.txt", func(row map[string]string) error {
+		t := Transfer{FromStopID: row["from_stop_id"], ToStopID: row["to_stop_id"]}
+		if row["transfer_type"] != "" {
+			transferType, err := strconv.Atoi(row["transfer_type"])
+			if err != nil {
+				return fmt.Errorf("transfer_type: %w", err)
+			}
+			t.Type = transferType
+		}
+		if row["min_transfer_time"] != "" {
+			minTime, err := strconv.Atoi(row["min_transfer_time"])
+			if err != nil {
+				return fmt.Errorf("min_transfer_time: %w", err)
+			}
+			t.MinTransferTime = &minTime
+		}
+		feed.Transfers = append(feed.Transfers, t)
+		return nil
+	})
+}
+
+// loadFrequencies populates Feed.Frequencies from frequencies.txt.
+func (feed *Feed) loadFrequencies() error {
+	return feed.readOptionalCsv("frequencies.txt", func(row map[string]string) error {
+		headway, err := strconv.Atoi(row["headway_secs"])
+		if err != nil {
+			return fmt.Errorf("headway_secs: %w", err)
+		}
+		feed.Frequencies = append(feed.Frequencies, Frequency{
+			TripID:      row["trip_id"],
+			StartTime:   hmstoi(row["start_time"]),
+			EndTime:     hmstoi(row["end_time"]),
+			HeadwaySecs: headway,
+		})
+		return nil
+	})
+}
+
+// loadFeedInfo populates Feed.FeedInfo from feed_info.txt, which holds at
+// most a single row.
+func (feed *Feed) loadFeedInfo() error {
+	return feed.readOptionalCsv("feed_info.txt", func(row map[string]string) error {
+		info := &FeedInfo{
+			PublisherName: row["feed_publisher_name"],
+			PublisherURL:  row["feed_publisher_url"],
+			Lang:          row["feed_lang"],
+			Version:       row["feed_version"],
+		}
+		if row["feed_start_date"] != "" {
+			info.StartDate = parseGTFSDate(row["feed_start_date"])
+		}
+		if row["feed_end_date"] != "" {
+			info.EndDate = parseGTFSDate(row["feed_end_date"])
+		}
+		feed.FeedInfo = info
+		return nil
+	})
+}
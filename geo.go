@@ -0,0 +1,73 @@
+package gtfs
+
+import (
+	"encoding/json"
+
+	"github.com/DanielOaks/go.gtfs/export"
+)
+
+// GeoJSON renders the shape as a single GeoJSON LineString Feature.
+func (shape Shape) GeoJSON() []byte {
+	feature := export.LineStringFeature(shape.coordPairs(), map[string]interface{}{
+		"shape_id": shape.ID,
+	})
+	data, _ := json.Marshal(feature)
+	return data
+}
+
+// EncodedPolyline renders the shape as a Google Encoded Polyline string.
+func (shape Shape) EncodedPolyline() string {
+	return export.EncodePolyline(shape.coordPairs())
+}
+
+func (shape Shape) coordPairs() [][2]float64 {
+	coords := make([][2]float64, len(shape.Coords))
+	for i, c := range shape.Coords {
+		coords[i] = [2]float64{c.Lon, c.Lat}
+	}
+	return coords
+}
+
+// GeoJSON renders every shape used by the route as a single GeoJSON
+// MultiLineString Feature, with the route's color, text color, and
+// headsigns as properties.
+func (route Route) GeoJSON() []byte {
+	lines := make([][][2]float64, 0, len(route.Shapes()))
+	for _, shape := range route.Shapes() {
+		if shape == nil {
+			continue
+		}
+		lines = append(lines, shape.coordPairs())
+	}
+
+	properties := map[string]interface{}{
+		"route_id":   route.ID,
+		"short_name": route.ShortName,
+		"long_name":  route.LongName,
+		"headsigns":  route.Headsigns(),
+	}
+	if route.Color != nil {
+		properties["color"] = *route.Color
+	}
+	if route.TextColor != nil {
+		properties["text_color"] = *route.TextColor
+	}
+
+	feature := export.MultiLineStringFeature(lines, properties)
+	data, _ := json.Marshal(feature)
+	return data
+}
+
+// StopsGeoJSON renders every stop in the feed as a GeoJSON
+// FeatureCollection of Points.
+func (feed Feed) StopsGeoJSON() []byte {
+	features := make([]export.Feature, 0, len(feed.Stops))
+	for _, stop := range feed.Stops {
+		features = append(features, export.PointFeature(stop.Coord.Lon, stop.Coord.Lat, map[string]interface{}{
+			"stop_id": stop.ID,
+			"name":    stop.Name,
+		}))
+	}
+	data, _ := json.Marshal(export.NewFeatureCollection(features...))
+	return data
+}
@@ -0,0 +1,15 @@
+package gtfs
+
+import "testing"
+
+func TestRouteGeoJSONHandlesTripsWithoutShape(t *testing.T) {
+	// shapes.txt is optional in GTFS; a trip with no (or unknown) shape_id
+	// has a nil Trip.Shape, which GeoJSON must tolerate rather than panic on.
+	trip := &Trip{ID: "T1", Direction: "0", Headsign: "Downtown"}
+	route := Route{ID: "R1", Trips: []*Trip{trip}}
+
+	data := route.GeoJSON()
+	if len(data) == 0 {
+		t.Fatal("GeoJSON() returned empty output")
+	}
+}
@@ -1,25 +1,54 @@
 package gtfs
 
 import (
+	"archive/zip"
+	"bytes"
 	"fmt"
+	"io/fs"
 	"io/ioutil"
-	"log"
-	"path"
+	"net/http"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
-
-	tablib "github.com/agrison/go-tablib"
+	"time"
 )
 
 // Feed represents a collection of GTFS information.
 type Feed struct {
+	// Dir records where the feed was loaded from: a directory path, a zip
+	// path, or a URL. It is kept for diagnostics only.
 	Dir             string
 	Routes          map[string]*Route
 	Shapes          map[string]*Shape
 	Stops           map[string]*Stop
 	Trips           map[string]*Trip
 	CalendarEntries map[string]CalendarEntry
+
+	// CalendarExceptions holds calendar_dates.txt entries, keyed by service
+	// ID and then by date (in YYYYMMDD form, matching the CSV).
+	CalendarExceptions map[string]map[string]CalendarException
+
+	// Agencies, Transfers, Frequencies, and FeedInfo are populated from
+	// agency.txt, transfers.txt, frequencies.txt, and feed_info.txt, all of
+	// which are optional in the GTFS spec.
+	Agencies    map[string]*Agency
+	Transfers   []Transfer
+	Frequencies []Frequency
+	FeedInfo    *FeedInfo
+
+	// Report records rows that failed to parse during Load rather than
+	// dropping them silently.
+	Report LoadReport
+
+	// fsys is where readCsv looks up GTFS files. It is a directory, a zip
+	// reader over an archive on disk, or a zip reader over a downloaded URL
+	// held in memory.
+	fsys fs.FS
+
+	// stopIdx backs NearestStops, StopsWithin, and StopsInBBox. It is built
+	// lazily on first query; see InvalidateStopIndex.
+	stopIdx *stopIndex
 }
 
 // RouteType describes the type of vehicle uses a particular route.
@@ -48,6 +77,10 @@ type Route struct {
 	Color       *string
 	TextColor   *string
 	Trips       []*Trip
+
+	// feed lets Route methods such as TripsOn resolve calendar information
+	// without every caller having to thread the Feed through.
+	feed *Feed
 }
 
 // Trip reprents a journey taken by a vehicle through stops.
@@ -88,11 +121,20 @@ type StopTime struct {
 	Trip *Trip
 	Time int
 	Seq  int
+
+	// Delay and Canceled are populated by Feed.ApplyRealtime and are zero/false
+	// until a realtime update has been applied.
+	Delay    int
+	Canceled bool
 }
 
+// CalendarEntry is a parsed calendar.txt row: the weekly mask of days a
+// service runs, bounded by a start and end date.
 type CalendarEntry struct {
 	ServiceID string
 	Days      []string
+	Start     time.Time
+	End       time.Time
 }
 
 // StopTimeBySeq is used to sort StopTimes by their sequence number.
@@ -116,55 +158,130 @@ func (a CoordBySeq) Len() int           { return len(a) }
 func (a CoordBySeq) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a CoordBySeq) Less(i, j int) bool { return a[i].Seq < a[j].Seq }
 
-// main utility function for reading GTFS files
-func (feed *Feed) readCsv(filename string, f func(map[string]interface{})) error {
-	fileData, err := ioutil.ReadFile(path.Join(feed.Dir, filename))
+// Load retrieves data from feedPath and returns a Feed containing that data.
+// feedPath may be a directory, a path to a .zip archive, or an http(s)://
+// URL pointing at a GTFS zip. Any handlers are registered as in
+// Feed.runRowHandlers, for GTFS files this module doesn't parse itself.
+//
+// Load returns a *Feed, not a Feed, so that the Feed a Route or Trip points
+// back into (see Route.TripsOn) is the same instance the caller holds.
+func Load(feedPath string, loadStopTimes bool, handlers ...RowHandler) (*Feed, error) {
+	if strings.HasPrefix(feedPath, "http://") || strings.HasPrefix(feedPath, "https://") {
+		return LoadURL(feedPath, loadStopTimes, handlers...)
+	}
+	if strings.HasSuffix(feedPath, ".zip") {
+		return LoadZip(feedPath, loadStopTimes, handlers...)
+	}
+	return loadFS(os.DirFS(feedPath), feedPath, loadStopTimes, handlers...)
+}
+
+// LoadZip retrieves data from a GTFS zip archive on disk and returns a Feed
+// containing that data. The archive is read directly, without unpacking to a
+// temp directory.
+func LoadZip(zipPath string, loadStopTimes bool, handlers ...RowHandler) (*Feed, error) {
+	r, err := zip.OpenReader(zipPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	dataset, err := tablib.LoadCSV(fileData)
+	defer r.Close()
+
+	return loadFS(rootedFS(r), zipPath, loadStopTimes, handlers...)
+}
+
+// LoadURL fetches a GTFS zip from a remote URL and returns a Feed containing
+// that data. The archive is read out of memory, without unpacking to a temp
+// directory or writing the download to disk.
+func LoadURL(url string, loadStopTimes bool, handlers ...RowHandler) (*Feed, error) {
+	resp, err := http.Get(url)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	// need to build list of rows to grab
-	rowIDs := make([]int, dataset.Height())
-	for i := range rowIDs {
-		rowIDs[i] = i
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("gtfs: fetching %s: unexpected status %s", url, resp.Status)
 	}
 
-	fmt.Println(filename, dataset.Height())
-	rows, err := dataset.Rows(rowIDs...)
+	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Fatal(fmt.Sprintf("Could not load rows: %s", err.Error()))
+		return nil, err
 	}
-	for _, row := range rows {
-		f(row)
+
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return loadFS(rootedFS(r), url, loadStopTimes, handlers...)
 }
 
-// Load retrieves data from the given directory path and returns a Feed containing that data.
-func Load(feedPath string, loadStopTimes bool) Feed {
-	f := Feed{Dir: feedPath}
+// rootedFS strips a single common top-level directory from fsys, if one
+// exists. Many GTFS zips package their .txt files under one folder (e.g.
+// "google_transit/") rather than at the archive root; without this, lookups
+// like readCsv("routes.txt") would never find anything. fsys is returned
+// unchanged if its root holds anything other than exactly one directory.
+func rootedFS(fsys fs.FS) fs.FS {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return fsys
+	}
+
+	sub, err := fs.Sub(fsys, entries[0].Name())
+	if err != nil {
+		return fsys
+	}
+	return sub
+}
+
+// loadFS does the actual parsing of a GTFS feed out of fsys, which may back
+// onto a directory or a zip archive.
+func loadFS(fsys fs.FS, label string, loadStopTimes bool, handlers ...RowHandler) (*Feed, error) {
+	f := &Feed{Dir: label, fsys: fsys}
 	f.Routes = make(map[string]*Route)
 	f.Shapes = make(map[string]*Shape)
 	f.Stops = make(map[string]*Stop)
 	f.Trips = make(map[string]*Trip)
 	f.CalendarEntries = make(map[string]CalendarEntry)
+	f.CalendarExceptions = make(map[string]map[string]CalendarException)
+	f.Agencies = make(map[string]*Agency)
+
+	if err := f.loadAgencies(); err != nil {
+		return nil, err
+	}
 
-	f.readCsv("calendar.txt", func(s map[string]interface{}) {
-		c := CalendarEntry{ServiceID: s["service_id"].(string), Days: []string{s["monday"].(string), s["tuesday"].(string), s["wednesday"].(string), s["thursday"].(string), s["friday"].(string), s["saturday"].(string), s["sunday"].(string)}}
-		f.CalendarEntries[s["service_id"].(string)] = c
-	})
+	// calendar.txt and calendar_dates.txt are each individually optional -
+	// the spec only requires that a feed provide at least one of them.
+	if err := f.readOptionalCsv("calendar.txt", func(row map[string]string) error {
+		serviceID := row["service_id"]
+		c := CalendarEntry{
+			ServiceID: serviceID,
+			Days:      []string{row["monday"], row["tuesday"], row["wednesday"], row["thursday"], row["friday"], row["saturday"], row["sunday"]},
+			Start:     parseGTFSDate(row["start_date"]),
+			End:       parseGTFSDate(row["end_date"]),
+		}
+		f.CalendarEntries[serviceID] = c
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := f.readOptionalCsv("calendar_dates.txt", func(row map[string]string) error {
+		serviceID := row["service_id"]
+		if f.CalendarExceptions[serviceID] == nil {
+			f.CalendarExceptions[serviceID] = make(map[string]CalendarException)
+		}
+		f.CalendarExceptions[serviceID][row["date"]] = CalendarException{Added: row["exception_type"] == "1"}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
 
 	// we assume that this CSV is grouped by shape_id
 	// but this is not guaranteed in spec?
 	var curShape *Shape
 	var found = false
-	f.readCsv("shapes.txt", func(s map[string]interface{}) {
-		shapeID := s["shape_id"].(string)
+	if err := f.readOptionalCsv("shapes.txt", func(row map[string]string) error {
+		shapeID := row["shape_id"]
 		if !found || shapeID != curShape.ID {
 			if found {
 				f.Shapes[curShape.ID] = curShape
@@ -172,11 +289,23 @@ func Load(feedPath string, loadStopTimes bool) Feed {
 			found = true
 			curShape = &Shape{ID: shapeID}
 		}
-		lon, _ := strconv.ParseFloat(s["shape_pt_lon"].(string), 64)
-		lat, _ := strconv.ParseFloat(s["shape_pt_lat"].(string), 64)
-		seq, _ := strconv.Atoi(s["shape_pt_sequence"].(string))
+		lon, err := strconv.ParseFloat(row["shape_pt_lon"], 64)
+		if err != nil {
+			return err
+		}
+		lat, err := strconv.ParseFloat(row["shape_pt_lat"], 64)
+		if err != nil {
+			return err
+		}
+		seq, err := strconv.Atoi(row["shape_pt_sequence"])
+		if err != nil {
+			return err
+		}
 		curShape.Coords = append(curShape.Coords, Coord{Lat: lat, Lon: lon, Seq: seq})
-	})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
 	if found {
 		f.Shapes[curShape.ID] = curShape
 	}
@@ -186,38 +315,43 @@ func Load(feedPath string, loadStopTimes bool) Feed {
 		sort.Sort(CoordBySeq(v.Coords))
 	}
 
-	f.readCsv("routes.txt", func(s map[string]interface{}) {
-		rsn := strings.TrimSpace(s["route_short_name"].(string))
-		rln := strings.TrimSpace(s["route_long_name"].(string))
-		id := strings.TrimSpace(s["route_id"].(string))
+	if err := f.readCsv("routes.txt", func(row map[string]string) error {
+		rsn := row["route_short_name"]
+		rln := row["route_long_name"]
+		id := row["route_id"]
 		var aid *string
-		if s["agency_id"] != nil {
-			aidString := strings.TrimSpace(s["agency_id"].(string))
+		if row["agency_id"] != "" {
+			aidString := row["agency_id"]
 			aid = &aidString
 		}
 		var desc *string
-		if s["description"] != nil {
-			descString := strings.TrimSpace(s["description"].(string))
+		if row["description"] != "" {
+			descString := row["description"]
 			desc = &descString
 		}
 		var url *string
-		if s["url"] != nil {
-			urlString := strings.TrimSpace(s["url"].(string))
+		if row["url"] != "" {
+			urlString := row["url"]
 			url = &urlString
 		}
 		var color *string
-		if s["route_color"] != nil {
-			colorString := strings.TrimSpace(s["route_color"].(string))
+		if row["route_color"] != "" {
+			colorString := row["route_color"]
 			color = &colorString
 		}
 		var textColor *string
-		if s["text_color"] != nil {
-			textColorString := strings.TrimSpace(s["text_color"].(string))
+		if row["text_color"] != "" {
+			textColorString := row["text_color"]
 			textColor = &textColorString
 		}
-		// we assume this will always be right
-		routeTypeInt, _ := strconv.Atoi(s["route_type"].(string))
-		routeTypeID := RouteType(routeTypeInt)
+		routeTypeID := RouteType(0)
+		if row["route_type"] != "" {
+			routeTypeInt, err := strconv.Atoi(row["route_type"])
+			if err != nil {
+				return err
+			}
+			routeTypeID = RouteType(routeTypeInt)
+		}
 		f.Routes[id] = &Route{
 			ID:          id,
 			AgencyID:    aid,
@@ -228,59 +362,92 @@ func Load(feedPath string, loadStopTimes bool) Feed {
 			URL:         url,
 			Color:       color,
 			TextColor:   textColor,
+			feed:        f,
 		}
-	})
-
-	f.readCsv("trips.txt", func(s map[string]interface{}) {
-		routeID := s["route_id"].(string)
-		service := s["service_id"].(string)
-		tripID := s["trip_id"].(string)
-		direction := s["direction_id"].(string)
-		shapeID := s["shape_id"].(string)
-		headsign := s["trip_headsign"].(string)
-
-		var shape *Shape
-		shape = f.Shapes[shapeID]
-		var trip Trip
-		trip.StopTimes = []StopTime{}
-		f.Trips[tripID] = &trip
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := f.readCsv("trips.txt", func(row map[string]string) error {
+		routeID := row["route_id"]
+		service := row["service_id"]
+		tripID := row["trip_id"]
+		direction := row["direction_id"]
+		shapeID := row["shape_id"]
+		headsign := row["trip_headsign"]
 
+		shape := f.Shapes[shapeID]
 		route := f.Routes[routeID]
-		trip = Trip{Shape: shape, Route: route, ID: tripID, Direction: direction, Service: service, Headsign: headsign}
-		route.Trips = append(route.Trips, &trip)
-		f.Routes[routeID] = route
-	})
-
-	f.readCsv("stops.txt", func(s map[string]interface{}) {
-		stopID := s["stop_id"].(string)
-		stopName := s["stop_name"].(string)
-		stopLat, _ := strconv.ParseFloat(s["stop_lat"].(string), 64)
-		stopLon, _ := strconv.ParseFloat(s["stop_lon"].(string), 64)
-		coord := Coord{Lat: stopLat, Lon: stopLon}
-		f.Stops[stopID] = &Stop{Coord: coord, Name: stopName, ID: stopID}
-	})
+		trip := &Trip{Shape: shape, Route: route, ID: tripID, Direction: direction, Service: service, Headsign: headsign}
+		f.Trips[tripID] = trip
+		if route != nil {
+			route.Trips = append(route.Trips, trip)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := f.readCsv("stops.txt", func(row map[string]string) error {
+		stopID := row["stop_id"]
+		stopLat, err := strconv.ParseFloat(row["stop_lat"], 64)
+		if err != nil {
+			return err
+		}
+		stopLon, err := strconv.ParseFloat(row["stop_lon"], 64)
+		if err != nil {
+			return err
+		}
+		f.Stops[stopID] = &Stop{Coord: Coord{Lat: stopLat, Lon: stopLon}, Name: row["stop_name"], ID: stopID}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := f.loadTransfers(); err != nil {
+		return nil, err
+	}
+	if err := f.loadFrequencies(); err != nil {
+		return nil, err
+	}
+	if err := f.loadFeedInfo(); err != nil {
+		return nil, err
+	}
 
 	if !loadStopTimes {
-		return f
-	}
-	f.readCsv("stop_times.txt", func(s map[string]interface{}) {
-		tripID := s["trip_id"].(string)
-		stopID := s["stop_id"].(string)
-		seq, _ := strconv.Atoi(s["stop_sequence"].(string))
-		time := hmstoi(s["arrival_time"].(string))
-		stop := f.Stops[stopID]
-		trip := f.Trips[tripID]
-		newStopTime := StopTime{Trip: trip, Stop: stop, Seq: seq, Time: time}
-		trip.StopTimes = append(trip.StopTimes, newStopTime)
-	})
+		if err := f.runRowHandlers(handlers); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
 
-	// sort stops by seq
+	if err := f.readCsv("stop_times.txt", func(row map[string]string) error {
+		tripID := row["trip_id"]
+		stopID := row["stop_id"]
+		seq, err := strconv.Atoi(row["stop_sequence"])
+		if err != nil {
+			return err
+		}
+		newStopTime := StopTime{Trip: f.Trips[tripID], Stop: f.Stops[stopID], Seq: seq, Time: hmstoi(row["arrival_time"])}
+		if trip := f.Trips[tripID]; trip != nil {
+			trip.StopTimes = append(trip.StopTimes, newStopTime)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
 
+	// sort stops by seq
 	for _, v := range f.Trips {
 		sort.Sort(StopTimeBySeq(v.StopTimes))
 	}
 
-	return f
+	if err := f.runRowHandlers(handlers); err != nil {
+		return nil, err
+	}
+
+	return f, nil
 }
 
 // RouteByShortName searches for and returns a route based on its short name, if it exists.
@@ -322,9 +489,19 @@ func (route Route) LongestShape() *Shape {
 	return shape
 }
 
+// parseGTFSDate parses a calendar.txt/calendar_dates.txt date in its native
+// YYYYMMDD form. An unparsable date yields the zero time.
+func parseGTFSDate(s string) time.Time {
+	t, _ := time.Parse("20060102", s)
+	return t
+}
+
 // hmstoi returns the number of seconds for a given time string.
 func hmstoi(str string) int {
 	components := strings.Split(str, ":")
+	if len(components) != 3 {
+		return 0
+	}
 	hour, _ := strconv.Atoi(components[0])
 	min, _ := strconv.Atoi(components[1])
 	sec, _ := strconv.Atoi(components[2])
@@ -358,6 +535,9 @@ func (route Route) Headsigns() []string {
 	maxHeadsign1 := ""
 
 	for _, t := range route.Trips {
+		if t.Shape == nil {
+			continue
+		}
 		if t.Direction == "0" {
 			if len(t.Shape.Coords) > max0 {
 				max0 = len(t.Shape.Coords)
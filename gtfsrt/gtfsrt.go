@@ -0,0 +1,148 @@
+// Package gtfsrt decodes GTFS-Realtime FeedMessage payloads (TripUpdate,
+// VehiclePosition, and Alert entities) into plain, ID-keyed Go values. It has
+// no dependency on the static gtfs package; callers are expected to resolve
+// the TripID/StopID/RouteID fields against their own loaded Feed.
+//
+// This is a deliberate deviation from affected entities being pointers into
+// a gtfs.Feed (Route/Stop/Trip): keeping gtfsrt free of a gtfs import lets it
+// be used standalone, and decoding never has a Feed to resolve against
+// anyway. The ID-to-pointer resolution happens one layer up: StopTimeUpdates
+// are resolved automatically by Feed.ApplyRealtime, and an Alert's affected
+// entities can be resolved on demand with Feed.ResolveAlert.
+package gtfsrt
+
+import (
+	"time"
+
+	pb "github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+	"google.golang.org/protobuf/proto"
+)
+
+// RealtimeUpdate describes a single stop_time_update within a TripUpdate,
+// matched by trip ID and stop sequence (falling back to stop ID when a feed
+// omits stop_sequence).
+type RealtimeUpdate struct {
+	TripID   string
+	StopID   string
+	StopSeq  int
+	Delay    int
+	Canceled bool
+}
+
+// VehiclePosition is the last reported location of a vehicle servicing a trip.
+type VehiclePosition struct {
+	TripID    string
+	RouteID   string
+	Lat       float64
+	Lon       float64
+	Bearing   float64
+	Speed     float64
+	Timestamp time.Time
+}
+
+// Alert is a service alert affecting one or more routes, stops, or trips for
+// some validity period.
+type Alert struct {
+	Header      string
+	Description string
+	Cause       string
+	Effect      string
+	RouteIDs    []string
+	StopIDs     []string
+	TripIDs     []string
+	Start       time.Time
+	End         time.Time
+}
+
+// Event is one decoded FeedMessage, fanned out as a unit so a consumer can
+// see everything that arrived in a single poll together. Err is set instead
+// of the other fields when fetching or decoding the feed failed.
+type Event struct {
+	Updates  []RealtimeUpdate
+	Vehicles []VehiclePosition
+	Alerts   []Alert
+	Err      error
+}
+
+// Decode parses a serialized GTFS-Realtime FeedMessage into its constituent
+// trip updates, vehicle positions, and alerts.
+func Decode(data []byte) (Event, error) {
+	var msg pb.FeedMessage
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return Event{}, err
+	}
+
+	var event Event
+	for _, entity := range msg.Entity {
+		if tu := entity.GetTripUpdate(); tu != nil {
+			tripID := tu.GetTrip().GetTripId()
+			for _, stu := range tu.GetStopTimeUpdate() {
+				delay := stu.GetArrival().GetDelay()
+				if stu.GetArrival() == nil {
+					// Some feeds only populate departure, e.g. for the first
+					// stop of a trip, which has no arrival to be delayed.
+					delay = stu.GetDeparture().GetDelay()
+				}
+				event.Updates = append(event.Updates, RealtimeUpdate{
+					TripID:   tripID,
+					StopID:   stu.GetStopId(),
+					StopSeq:  int(stu.GetStopSequence()),
+					Delay:    int(delay),
+					Canceled: stu.GetScheduleRelationship() == pb.TripUpdate_StopTimeUpdate_SKIPPED,
+				})
+			}
+		}
+
+		if vp := entity.GetVehicle(); vp != nil {
+			event.Vehicles = append(event.Vehicles, VehiclePosition{
+				TripID:    vp.GetTrip().GetTripId(),
+				RouteID:   vp.GetTrip().GetRouteId(),
+				Lat:       float64(vp.GetPosition().GetLatitude()),
+				Lon:       float64(vp.GetPosition().GetLongitude()),
+				Bearing:   float64(vp.GetPosition().GetBearing()),
+				Speed:     float64(vp.GetPosition().GetSpeed()),
+				Timestamp: time.Unix(int64(vp.GetTimestamp()), 0),
+			})
+		}
+
+		if al := entity.GetAlert(); al != nil {
+			alert := Alert{
+				Header:      translatedText(al.GetHeaderText()),
+				Description: translatedText(al.GetDescriptionText()),
+				Cause:       al.GetCause().String(),
+				Effect:      al.GetEffect().String(),
+			}
+			for _, informed := range al.GetInformedEntity() {
+				if id := informed.GetRouteId(); id != "" {
+					alert.RouteIDs = append(alert.RouteIDs, id)
+				}
+				if id := informed.GetStopId(); id != "" {
+					alert.StopIDs = append(alert.StopIDs, id)
+				}
+				if id := informed.GetTrip().GetTripId(); id != "" {
+					alert.TripIDs = append(alert.TripIDs, id)
+				}
+			}
+			for _, period := range al.GetActivePeriod() {
+				if period.Start != nil {
+					alert.Start = time.Unix(int64(period.GetStart()), 0)
+				}
+				if period.End != nil {
+					alert.End = time.Unix(int64(period.GetEnd()), 0)
+				}
+			}
+			event.Alerts = append(event.Alerts, alert)
+		}
+	}
+
+	return event, nil
+}
+
+// translatedText returns the first translation of a GTFS-Realtime
+// TranslatedString, which is the common case for single-language feeds.
+func translatedText(t *pb.TranslatedString) string {
+	if t == nil || len(t.GetTranslation()) == 0 {
+		return ""
+	}
+	return t.GetTranslation()[0].GetText()
+}
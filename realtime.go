@@ -0,0 +1,112 @@
+package gtfs
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/DanielOaks/go.gtfs/gtfsrt"
+)
+
+// ApplyRealtime decodes a single GTFS-Realtime FeedMessage payload and
+// overlays it onto the feed: matching trip updates set StopTime.Delay and
+// StopTime.Canceled (by trip ID and stop sequence, falling back to stop ID),
+// and the decoded vehicle positions and alerts are returned unchanged for the
+// caller to act on.
+func (feed *Feed) ApplyRealtime(data []byte) (gtfsrt.Event, error) {
+	event, err := gtfsrt.Decode(data)
+	if err != nil {
+		return gtfsrt.Event{}, err
+	}
+
+	for _, u := range event.Updates {
+		trip, ok := feed.Trips[u.TripID]
+		if !ok {
+			continue
+		}
+		for i := range trip.StopTimes {
+			st := &trip.StopTimes[i]
+			matches := st.Seq == u.StopSeq
+			if u.StopSeq == 0 && st.Stop != nil {
+				matches = st.Stop.ID == u.StopID
+			}
+			if matches {
+				st.Delay = u.Delay
+				st.Canceled = u.Canceled
+			}
+		}
+	}
+
+	return event, nil
+}
+
+// ResolveAlert looks up the routes, stops, and trips an Alert affects
+// against this feed, giving the *Route/*Stop/*Trip pointers that gtfsrt
+// itself can't provide (see the deviation noted on the gtfsrt package).
+// IDs with no match in the feed are omitted.
+func (feed *Feed) ResolveAlert(a gtfsrt.Alert) (routes []*Route, stops []*Stop, trips []*Trip) {
+	for _, id := range a.RouteIDs {
+		if r, ok := feed.Routes[id]; ok {
+			routes = append(routes, r)
+		}
+	}
+	for _, id := range a.StopIDs {
+		if s, ok := feed.Stops[id]; ok {
+			stops = append(stops, s)
+		}
+	}
+	for _, id := range a.TripIDs {
+		if t, ok := feed.Trips[id]; ok {
+			trips = append(trips, t)
+		}
+	}
+	return routes, stops, trips
+}
+
+// PollRealtime fetches the GTFS-Realtime feed at url every interval, applies
+// each payload to the feed via ApplyRealtime, and fans the decoded events out
+// through the returned channel. Polling stops and the channel is closed when
+// ctx is done.
+func (feed *Feed) PollRealtime(url string, interval time.Duration, ctx context.Context) <-chan gtfsrt.Event {
+	out := make(chan gtfsrt.Event)
+
+	go func() {
+		defer close(out)
+
+		for {
+			event, err := feed.fetchAndApplyRealtime(url)
+			if err != nil {
+				event.Err = err
+			}
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case <-time.After(interval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+func (feed *Feed) fetchAndApplyRealtime(url string) (gtfsrt.Event, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return gtfsrt.Event{}, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return gtfsrt.Event{}, err
+	}
+
+	return feed.ApplyRealtime(data)
+}
@@ -0,0 +1,35 @@
+package gtfs
+
+import (
+	"testing"
+
+	"github.com/DanielOaks/go.gtfs/gtfsrt"
+)
+
+func TestResolveAlert(t *testing.T) {
+	route := &Route{ID: "R1"}
+	stop := &Stop{ID: "S1"}
+	trip := &Trip{ID: "T1"}
+	feed := &Feed{
+		Routes: map[string]*Route{route.ID: route},
+		Stops:  map[string]*Stop{stop.ID: stop},
+		Trips:  map[string]*Trip{trip.ID: trip},
+	}
+
+	alert := gtfsrt.Alert{
+		RouteIDs: []string{"R1", "unknown-route"},
+		StopIDs:  []string{"S1"},
+		TripIDs:  []string{"T1"},
+	}
+
+	routes, stops, trips := feed.ResolveAlert(alert)
+	if len(routes) != 1 || routes[0] != route {
+		t.Fatalf("routes = %v, want [%v]", routes, route)
+	}
+	if len(stops) != 1 || stops[0] != stop {
+		t.Fatalf("stops = %v, want [%v]", stops, stop)
+	}
+	if len(trips) != 1 || trips[0] != trip {
+		t.Fatalf("trips = %v, want [%v]", trips, trip)
+	}
+}
@@ -0,0 +1,84 @@
+package gtfs
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ScheduledJourney is one trip's passage between two stops: when it departs
+// the first, when it arrives at the second.
+type ScheduledJourney struct {
+	DepartureTime int
+	ArrivalTime   int
+	Trip          *Trip
+}
+
+// IntermediateStops returns the ordered list of stops between fromStopID and
+// toStopID (inclusive), in the given direction, taken from the first trip on
+// the route in that direction whose stop sequence visits both stops. If that
+// trip visits toStopID before fromStopID, an error is returned; later trips
+// are not searched.
+func (route Route) IntermediateStops(fromStopID, toStopID, direction string) ([]*Stop, error) {
+	for _, t := range route.Trips {
+		if t.Direction != direction {
+			continue
+		}
+
+		fromIdx, toIdx := tripStopIndices(t, fromStopID, toStopID)
+		if fromIdx == -1 || toIdx == -1 {
+			continue
+		}
+		if fromIdx > toIdx {
+			return nil, fmt.Errorf("gtfs: stop %s comes after stop %s on trip %s in direction %s", fromStopID, toStopID, t.ID, direction)
+		}
+
+		stops := make([]*Stop, 0, toIdx-fromIdx+1)
+		for _, st := range t.StopTimes[fromIdx : toIdx+1] {
+			stops = append(stops, st.Stop)
+		}
+		return stops, nil
+	}
+
+	return nil, fmt.Errorf("gtfs: no trip on route %s visits both %s and %s in direction %s", route.ID, fromStopID, toStopID, direction)
+}
+
+// Schedule returns every trip running on day that visits both fromStopID and
+// toStopID in order, as the departure/arrival pair at those stops, sorted by
+// departure time.
+func (route Route) Schedule(fromStopID, toStopID string, day time.Time) []ScheduledJourney {
+	retval := []ScheduledJourney{}
+	for _, t := range route.TripsOn(day) {
+		fromIdx, toIdx := tripStopIndices(t, fromStopID, toStopID)
+		if fromIdx == -1 || toIdx == -1 || fromIdx > toIdx {
+			continue
+		}
+		retval = append(retval, ScheduledJourney{
+			DepartureTime: t.StopTimes[fromIdx].Time,
+			ArrivalTime:   t.StopTimes[toIdx].Time,
+			Trip:          t,
+		})
+	}
+
+	sort.Slice(retval, func(i, j int) bool { return retval[i].DepartureTime < retval[j].DepartureTime })
+	return retval
+}
+
+// tripStopIndices returns the position of fromStopID and toStopID within
+// t.StopTimes (which is kept sorted by Seq), or -1 for either that isn't
+// visited by the trip.
+func tripStopIndices(t *Trip, fromStopID, toStopID string) (fromIdx, toIdx int) {
+	fromIdx, toIdx = -1, -1
+	for i, st := range t.StopTimes {
+		if st.Stop == nil {
+			continue
+		}
+		if st.Stop.ID == fromStopID {
+			fromIdx = i
+		}
+		if st.Stop.ID == toStopID {
+			toIdx = i
+		}
+	}
+	return fromIdx, toIdx
+}
@@ -0,0 +1,52 @@
+package gtfs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRouteSchedule(t *testing.T) {
+	stopA := &Stop{ID: "A"}
+	stopB := &Stop{ID: "B"}
+	stopC := &Stop{ID: "C"}
+
+	feed := &Feed{
+		CalendarEntries: map[string]CalendarEntry{
+			"daily": {
+				ServiceID: "daily",
+				Days:      []string{"1", "1", "1", "1", "1", "1", "1"},
+				Start:     parseGTFSDate("20260101"),
+				End:       parseGTFSDate("20261231"),
+			},
+		},
+		CalendarExceptions: map[string]map[string]CalendarException{},
+	}
+
+	early := &Trip{ID: "early", Service: "daily"}
+	early.StopTimes = []StopTime{
+		{Stop: stopA, Trip: early, Time: hmstoi("08:00:00"), Seq: 0},
+		{Stop: stopB, Trip: early, Time: hmstoi("08:10:00"), Seq: 1},
+		{Stop: stopC, Trip: early, Time: hmstoi("08:20:00"), Seq: 2},
+	}
+	late := &Trip{ID: "late", Service: "daily"}
+	late.StopTimes = []StopTime{
+		{Stop: stopA, Trip: late, Time: hmstoi("09:00:00"), Seq: 0},
+		{Stop: stopB, Trip: late, Time: hmstoi("09:10:00"), Seq: 1},
+		{Stop: stopC, Trip: late, Time: hmstoi("09:20:00"), Seq: 2},
+	}
+
+	route := Route{ID: "R1", Trips: []*Trip{late, early}, feed: feed}
+
+	day := time.Date(2026, time.July, 27, 0, 0, 0, 0, time.UTC)
+	schedule := route.Schedule("A", "C", day)
+
+	if len(schedule) != 2 {
+		t.Fatalf("Schedule returned %d journeys, want 2", len(schedule))
+	}
+	if schedule[0].Trip.ID != "early" || schedule[1].Trip.ID != "late" {
+		t.Fatalf("Schedule not sorted by departure time: got %s then %s", schedule[0].Trip.ID, schedule[1].Trip.ID)
+	}
+	if schedule[0].DepartureTime != hmstoi("08:00:00") || schedule[0].ArrivalTime != hmstoi("08:20:00") {
+		t.Fatalf("Schedule[0] = %+v, want departure 08:00:00 and arrival 08:20:00", schedule[0])
+	}
+}
@@ -0,0 +1,131 @@
+package gtfs
+
+import (
+	"math"
+	"sync"
+
+	"github.com/dhconnelly/rtreego"
+)
+
+// earthRadiusMeters is used for haversine distance calculations.
+const earthRadiusMeters = 6371000.0
+
+// stopIndex is the lazily-built R-tree backing Feed's nearest/radius/bbox
+// stop queries.
+type stopIndex struct {
+	mu   sync.Mutex
+	tree *rtreego.Rtree
+}
+
+// stopIndexEntry adapts a *Stop to rtreego.Spatial so it can be inserted
+// into the R-tree. Stops are treated as zero-size points.
+type stopIndexEntry struct {
+	stop *Stop
+}
+
+func (e stopIndexEntry) Bounds() rtreego.Rect {
+	const eps = 1e-9
+	rect, _ := rtreego.NewRect(rtreego.Point{e.stop.Coord.Lon, e.stop.Coord.Lat}, []float64{eps, eps})
+	return rect
+}
+
+// ensureStopIndex returns the feed's stop R-tree, building it on first call.
+func (feed *Feed) ensureStopIndex() *rtreego.Rtree {
+	if feed.stopIdx == nil {
+		feed.stopIdx = &stopIndex{}
+	}
+
+	feed.stopIdx.mu.Lock()
+	defer feed.stopIdx.mu.Unlock()
+	if feed.stopIdx.tree == nil {
+		tree := rtreego.NewTree(2, 25, 50)
+		for _, s := range feed.Stops {
+			tree.Insert(stopIndexEntry{stop: s})
+		}
+		feed.stopIdx.tree = tree
+	}
+	return feed.stopIdx.tree
+}
+
+// InvalidateStopIndex discards the cached spatial index used by
+// NearestStops, StopsWithin, and StopsInBBox. The index has no way to
+// observe direct mutation of Feed.Stops, so call this after adding,
+// removing, or relocating stops by hand; it will simply be rebuilt, lazily,
+// on the next query.
+func (feed *Feed) InvalidateStopIndex() {
+	if feed.stopIdx == nil {
+		return
+	}
+	feed.stopIdx.mu.Lock()
+	feed.stopIdx.tree = nil
+	feed.stopIdx.mu.Unlock()
+}
+
+// NearestStops returns the k stops closest to c, nearest first.
+func (feed *Feed) NearestStops(c Coord, k int) []*Stop {
+	tree := feed.ensureStopIndex()
+	results := tree.NearestNeighbors(k, rtreego.Point{c.Lon, c.Lat})
+
+	retval := make([]*Stop, 0, len(results))
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		retval = append(retval, r.(stopIndexEntry).stop)
+	}
+	return retval
+}
+
+// StopsWithin returns every stop within radiusMeters of c, filtered with the
+// haversine distance formula after a bounding-box prefilter on the index.
+func (feed *Feed) StopsWithin(c Coord, radiusMeters float64) []*Stop {
+	latDeg := radiusMeters / earthRadiusMeters * (180 / math.Pi)
+
+	// longitude degrees get narrower by a factor of cos(latitude) as you
+	// move away from the equator, so widen the box accordingly; clamp near
+	// the poles where cos(lat) approaches zero and the box would blow up.
+	cos := math.Cos(c.Lat * math.Pi / 180)
+	const minCos = 0.01
+	if cos < minCos {
+		cos = minCos
+	}
+	lonDeg := latDeg / cos
+
+	candidates := feed.StopsInBBox(c.Lat-latDeg, c.Lon-lonDeg, c.Lat+latDeg, c.Lon+lonDeg)
+
+	retval := []*Stop{}
+	for _, s := range candidates {
+		if haversine(c, s.Coord) <= radiusMeters {
+			retval = append(retval, s)
+		}
+	}
+	return retval
+}
+
+// StopsInBBox returns every stop within the given lat/lon bounding box.
+func (feed *Feed) StopsInBBox(minLat, minLon, maxLat, maxLon float64) []*Stop {
+	tree := feed.ensureStopIndex()
+	rect, err := rtreego.NewRect(rtreego.Point{minLon, minLat}, []float64{maxLon - minLon, maxLat - minLat})
+	if err != nil {
+		return nil
+	}
+
+	results := tree.SearchIntersect(rect)
+	retval := make([]*Stop, 0, len(results))
+	for _, r := range results {
+		retval = append(retval, r.(stopIndexEntry).stop)
+	}
+	return retval
+}
+
+// haversine returns the great-circle distance between two coordinates, in
+// meters.
+func haversine(a, b Coord) float64 {
+	lat1, lon1 := a.Lat*math.Pi/180, a.Lon*math.Pi/180
+	lat2, lon2 := b.Lat*math.Pi/180, b.Lon*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := lon2 - lon1
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
@@ -0,0 +1,29 @@
+package gtfs
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStopsWithinWidensLongitudeAtHighLatitude(t *testing.T) {
+	center := Coord{Lat: 60, Lon: 0}
+
+	// A stop roughly 1000.8m due east of center, at latitude 60 degrees,
+	// where a degree of longitude covers much less ground than a degree of
+	// latitude. Before scaling the bbox prefilter by cos(lat), this stop's
+	// bounding box test failed and it was dropped.
+	metersPerLonDegree := (math.Pi / 180) * earthRadiusMeters * math.Cos(center.Lat*math.Pi/180)
+	stop := &Stop{ID: "east-stop", Coord: Coord{Lat: 60, Lon: 1000.8 / metersPerLonDegree}}
+
+	feed := &Feed{Stops: map[string]*Stop{stop.ID: stop}}
+
+	dist := haversine(center, stop.Coord)
+	if dist < 999 || dist > 1002 {
+		t.Fatalf("test stop is %.1fm from center, want ~1000.8m", dist)
+	}
+
+	found := feed.StopsWithin(center, 1100)
+	if len(found) != 1 || found[0].ID != "east-stop" {
+		t.Fatalf("StopsWithin(center, 1100) = %v, want [east-stop]", found)
+	}
+}